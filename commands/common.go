@@ -1,7 +1,10 @@
 package commands
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"github.com/eldada/metrics-viewer/internal/log"
 	"github.com/eldada/metrics-viewer/provider"
 	"github.com/jfrog/jfrog-cli-core/artifactory/commands"
 	"github.com/jfrog/jfrog-cli-core/plugins/components"
@@ -60,6 +63,37 @@ var AggregateIgnoreLabelsFlag = components.StringFlag{
 	DefaultValue: "start,end,status",
 }
 
+var LabelPassFlag = components.StringFlag{
+	Name:        "label-pass",
+	Description: "semicolon delimited list of label=regex conditions; a sample is kept only if all conditions match. Use regex alternation (label=v1|v2) to match multiple values for one label",
+}
+
+var LabelDropFlag = components.StringFlag{
+	Name:        "label-drop",
+	Description: "semicolon delimited list of label=regex conditions, evaluated after --label-pass; a sample matching any condition is dropped",
+}
+
+var CacheDirFlag = components.StringFlag{
+	Name:        "cache-dir",
+	Description: "directory to persist successful scrapes to, and to fall back to when the scrape endpoint becomes unavailable",
+}
+
+var LogLevelFlag = components.StringFlag{
+	Name:         "log-level",
+	Description:  "log level for diagnostic records: DEBUG, INFO, WARN or ERROR",
+	DefaultValue: "INFO",
+}
+
+var LogFileFlag = components.StringFlag{
+	Name:        "log-file",
+	Description: "file to write log records to; defaults to stderr",
+}
+
+var ConfigFlag = components.StringFlag{
+	Name:        "config",
+	Description: "path to a YAML config file declaring multiple scrape targets; mutually exclusive with --file/--url/--artifactory",
+}
+
 func getCommonFlags() []components.Flag {
 	return []components.Flag{
 		FileFlag,
@@ -68,24 +102,53 @@ func getCommonFlags() []components.Flag {
 		PasswordFlag,
 		ArtifactoryFlag,
 		ServerFlag,
+		ConfigFlag,
 		IntervalFlag,
 		FilterFlag,
 		AggregateIgnoreLabelsFlag,
+		LabelPassFlag,
+		LabelDropFlag,
+		CacheDirFlag,
+		LogLevelFlag,
+		LogFileFlag,
 	}
 }
 
 type commonConfiguration struct {
 	file                  string
 	urlMetricsFetcher     provider.UrlMetricsFetcher
+	targets               []target
 	interval              time.Duration
 	filter                *regexp.Regexp
 	aggregateIgnoreLabels provider.StringSet
+	labelFilter           provider.LabelFilter
+	cacheDir              string
+	logger                *log.Logger
 }
 
 func (c commonConfiguration) UrlMetricsFetcher() provider.UrlMetricsFetcher {
 	return c.urlMetricsFetcher
 }
 
+// Targets returns the set of scrape targets declared via --config. When a
+// single source flag (--file/--url/--artifactory) was used instead, it
+// returns a single target named "default" wrapping that same fetcher, so downstream
+// aggregation/display code can always key metrics by target.
+func (c commonConfiguration) Targets() []target {
+	if len(c.targets) > 0 {
+		return c.targets
+	}
+	return []target{
+		{
+			name:              "default",
+			urlMetricsFetcher: c.urlMetricsFetcher,
+			interval:          c.interval,
+			filter:            c.filter,
+			labelFilter:       c.labelFilter,
+		},
+	}
+}
+
 func (c commonConfiguration) File() string {
 	return c.file
 }
@@ -102,17 +165,50 @@ func (c commonConfiguration) AggregateIgnoreLabels() provider.StringSet {
 	return c.aggregateIgnoreLabels
 }
 
+// LabelFilter returns the tagpass/tagdrop predicate built from --label-pass
+// and --label-drop, to be applied to every scraped sample before aggregation.
+func (c commonConfiguration) LabelFilter() provider.LabelFilter {
+	return c.labelFilter
+}
+
+// Logger returns the leveled logger built from --log-level/--log-file, used
+// to report transient scrape failures, cache fallbacks and config
+// resolution without surfacing them as CLI errors.
+func (c commonConfiguration) Logger() *log.Logger {
+	return c.logger
+}
+
+// cacheFileName derives a filesystem-safe cache file name for a scrape
+// source, so that reusing the same --cache-dir across different sources
+// (e.g. --url A in one invocation, --url B sharing the cache dir in
+// another) never replays one source's snapshot as another's fallback.
+// label is a human-readable prefix (a target name, or "default" for the
+// single-source flags); source identifies the actual endpoint being
+// scraped (a URL, file path, or Artifactory server ID).
+func cacheFileName(label, source string) string {
+	digest := sha1.Sum([]byte(source))
+	return fmt.Sprintf("%s-%s", label, hex.EncodeToString(digest[:8]))
+}
+
 func (c commonConfiguration) String() string {
-	return fmt.Sprintf("file: '%s', %s, interval: %s, filter: %s",
-		c.file, c.urlMetricsFetcher, c.interval, c.filter.String())
+	return fmt.Sprintf("file: '%s', %s, interval: %s, filter: %s, labelFilter: %s, cacheDir: '%s', targets: %d",
+		c.file, c.urlMetricsFetcher, c.interval, c.filter.String(), c.labelFilter.String(), c.cacheDir, len(c.targets))
 }
 
 func parseCommonConfig(c *components.Context) (*commonConfiguration, error) {
 	conf := commonConfiguration{
 		file: c.GetStringFlagValue("file"),
 	}
+
+	logger, err := log.NewFromFlags(c.GetStringFlagValue("log-level"), c.GetStringFlagValue("log-file"))
+	if err != nil {
+		return nil, err
+	}
+	conf.logger = logger
+
 	url := c.GetStringFlagValue("url")
 	callArtifactory := c.GetBoolFlagValue("artifactory")
+	configPath := c.GetStringFlagValue("config")
 
 	countInputFlags := 0
 	if conf.file != "" {
@@ -124,19 +220,51 @@ func parseCommonConfig(c *components.Context) (*commonConfiguration, error) {
 	if callArtifactory {
 		countInputFlags++
 	}
+	if configPath != "" {
+		countInputFlags++
+	}
 	if countInputFlags == 0 && os.Getenv("MOCK_METRICS_DATA") == "" {
-		return nil, fmt.Errorf("one flag is required: --file | --url | --artifactory")
+		return nil, fmt.Errorf("one flag is required: --file | --url | --artifactory | --config")
 	}
 	if countInputFlags > 1 {
-		return nil, fmt.Errorf("only one flag is required: --file | --url | --artifactory")
+		return nil, fmt.Errorf("only one flag is required: --file | --url | --artifactory | --config")
+	}
+
+	conf.cacheDir = c.GetStringFlagValue("cache-dir")
+	if conf.cacheDir != "" {
+		info, err := os.Stat(conf.cacheDir)
+		if err != nil {
+			return nil, fmt.Errorf("could not access cache directory %s: %w", conf.cacheDir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("cache directory %s is not a directory", conf.cacheDir)
+		}
+		logger.Debug("using cache directory %s", conf.cacheDir)
 	}
 
+	if configPath != "" {
+		intervalValue := c.GetStringFlagValue("interval")
+		defaultIntervalSeconds, err := strconv.ParseInt(intervalValue, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse interval value: %s; cause: %w", intervalValue, err)
+		}
+		targets, err := loadTargetsConfig(configPath, time.Duration(defaultIntervalSeconds)*time.Second, conf.cacheDir, conf.logger)
+		if err != nil {
+			return nil, err
+		}
+		conf.targets = targets
+	}
+
+	var sourceKey string
+
 	if conf.file != "" {
 		f, err := os.Open(conf.file)
 		if err != nil {
 			return nil, fmt.Errorf("could not open file %s: %w", conf.file, err)
 		}
 		_ = f.Close()
+		conf.urlMetricsFetcher = provider.NewFileMetricsFetcher(conf.file)
+		sourceKey = "file:" + conf.file
 	}
 
 	if callArtifactory {
@@ -153,12 +281,22 @@ func parseCommonConfig(c *components.Context) (*commonConfiguration, error) {
 		if err != nil {
 			return nil, fmt.Errorf("could not initiate metrics fetcher from Artifactory; cause: %w", err)
 		}
+		sourceKey = "artifactory:" + serverId
 	}
 
 	if url != "" {
 		username := c.GetStringFlagValue("user")
 		password := c.GetStringFlagValue("password")
 		conf.urlMetricsFetcher = provider.NewUrlMetricsFetcher(url, username, password)
+		sourceKey = "url:" + url
+	}
+
+	if conf.cacheDir != "" && conf.urlMetricsFetcher != nil {
+		var err error
+		conf.urlMetricsFetcher, err = provider.NewCachingFetcher(conf.urlMetricsFetcher, conf.cacheDir, cacheFileName("default", sourceKey), conf.logger)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	var flagValue string
@@ -188,5 +326,10 @@ func parseCommonConfig(c *components.Context) (*commonConfiguration, error) {
 		conf.aggregateIgnoreLabels.Add(strings.Split(flagValue, ",")...)
 	}
 
+	conf.labelFilter, err = provider.NewLabelFilter(c.GetStringFlagValue("label-pass"), c.GetStringFlagValue("label-drop"))
+	if err != nil {
+		return nil, err
+	}
+
 	return &conf, nil
 }
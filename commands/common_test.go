@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheFileNameDiffersBySource(t *testing.T) {
+	a := cacheFileName("default", "url:https://a.example.com/metrics")
+	b := cacheFileName("default", "url:https://b.example.com/metrics")
+
+	if a == b {
+		t.Fatalf("expected different cache file names for different sources, got %q for both", a)
+	}
+	if cacheFileName("default", "url:https://a.example.com/metrics") != a {
+		t.Fatalf("cacheFileName is not deterministic for the same source")
+	}
+}
+
+func TestCommonConfigurationStringIncludesFullConfigSurface(t *testing.T) {
+	conf := commonConfiguration{
+		file:     "metrics.prom",
+		interval: 5 * time.Second,
+		filter:   regexp.MustCompile(".*"),
+		cacheDir: "/var/cache/metrics-viewer",
+		targets:  []target{{name: "primary"}, {name: "replica"}},
+	}
+
+	got := conf.String()
+	for _, want := range []string{"cacheDir: '/var/cache/metrics-viewer'", "targets: 2", "labelFilter:"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("String() = %q, want it to contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/eldada/metrics-viewer/internal/log"
+	"github.com/eldada/metrics-viewer/provider"
+	"github.com/jfrog/jfrog-cli-core/artifactory/commands"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// targetConfig describes a single scrape target loaded from a --config file.
+// It mirrors the single-source flags (file/url/artifactory) but allows many
+// of these to be declared side by side, each with its own filter and tags.
+type targetConfig struct {
+	Name        string            `yaml:"name"`
+	File        string            `yaml:"file"`
+	Url         string            `yaml:"url"`
+	User        string            `yaml:"user"`
+	Password    string            `yaml:"password"`
+	Artifactory bool              `yaml:"artifactory"`
+	Server      string            `yaml:"server"`
+	Interval    string            `yaml:"interval"`
+	Filter      string            `yaml:"filter"`
+	LabelPass   string            `yaml:"label_pass"`
+	LabelDrop   string            `yaml:"label_drop"`
+	Tags        map[string]string `yaml:"tags"`
+}
+
+// targetsFile is the top level document loaded from --config.
+type targetsFile struct {
+	Targets []targetConfig `yaml:"targets"`
+}
+
+// target is a fully resolved targetConfig, ready to be scraped and keyed by
+// name in commonConfiguration.
+type target struct {
+	name              string
+	urlMetricsFetcher provider.UrlMetricsFetcher
+	interval          time.Duration
+	filter            *regexp.Regexp
+	labelFilter       provider.LabelFilter
+	tags              map[string]string
+}
+
+func loadTargetsConfig(path string, defaultInterval time.Duration, cacheDir string, logger *log.Logger) ([]target, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file %s: %w", path, err)
+	}
+
+	var doc targetsFile
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("could not parse config file %s: %w", path, err)
+	}
+	if len(doc.Targets) == 0 {
+		return nil, fmt.Errorf("config file %s does not declare any targets", path)
+	}
+
+	targets := make([]target, 0, len(doc.Targets))
+	seenNames := provider.StringSet{}
+	for i, t := range doc.Targets {
+		resolved, err := resolveTarget(t, i, defaultInterval, cacheDir, logger)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target #%d (%s) in config file %s: %w", i, t.Name, path, err)
+		}
+		if seenNames.Contains(resolved.name) {
+			return nil, fmt.Errorf("duplicate target name %q in config file %s", resolved.name, path)
+		}
+		seenNames.Add(resolved.name)
+		targets = append(targets, resolved)
+	}
+
+	logger.Debug("resolved %d target(s) from config file %s", len(targets), path)
+	return targets, nil
+}
+
+func resolveTarget(t targetConfig, index int, defaultInterval time.Duration, cacheDir string, logger *log.Logger) (target, error) {
+	countInputFlags := 0
+	if t.File != "" {
+		countInputFlags++
+	}
+	if t.Url != "" {
+		countInputFlags++
+	}
+	if t.Artifactory {
+		countInputFlags++
+	}
+	if countInputFlags == 0 {
+		return target{}, fmt.Errorf("one of file | url | artifactory is required")
+	}
+	if countInputFlags > 1 {
+		return target{}, fmt.Errorf("only one of file | url | artifactory is allowed")
+	}
+
+	name := t.Name
+	if name == "" {
+		name = fmt.Sprintf("target-%d", index)
+	}
+
+	resolved := target{
+		name:     name,
+		tags:     t.Tags,
+		interval: defaultInterval,
+	}
+
+	if t.Interval != "" {
+		intValue, err := strconv.ParseInt(t.Interval, 10, 64)
+		if err != nil {
+			return target{}, fmt.Errorf("failed to parse interval value: %s; cause: %w", t.Interval, err)
+		}
+		if intValue <= 0 {
+			return target{}, fmt.Errorf("interval value must be positive; got: %d", intValue)
+		}
+		resolved.interval = time.Duration(intValue) * time.Second
+	}
+
+	var sourceKey string
+
+	switch {
+	case t.File != "":
+		resolved.urlMetricsFetcher = provider.NewFileMetricsFetcher(t.File)
+		sourceKey = "file:" + t.File
+	case t.Artifactory:
+		rtDetails, err := commands.GetConfig(t.Server, false)
+		if err != nil {
+			msg := fmt.Sprintf("could not load configuration for Artifactory server %s", t.Server)
+			if t.Server == "" {
+				msg = "could not load configuration for current Artifactory server"
+			}
+			return target{}, fmt.Errorf("%s; cause: %w", msg, err)
+		}
+		fetcher, err := provider.NewArtifactoryMetricsFetcher(rtDetails)
+		if err != nil {
+			return target{}, fmt.Errorf("could not initiate metrics fetcher from Artifactory; cause: %w", err)
+		}
+		resolved.urlMetricsFetcher = fetcher
+		sourceKey = "artifactory:" + t.Server
+	case t.Url != "":
+		resolved.urlMetricsFetcher = provider.NewUrlMetricsFetcher(t.Url, t.User, t.Password)
+		sourceKey = "url:" + t.Url
+	}
+
+	if cacheDir != "" {
+		cached, err := provider.NewCachingFetcher(resolved.urlMetricsFetcher, cacheDir, cacheFileName(name, sourceKey), logger)
+		if err != nil {
+			return target{}, err
+		}
+		resolved.urlMetricsFetcher = cached
+	}
+
+	resolved.filter = regexp.MustCompile(".*")
+	if t.Filter != "" {
+		compiled, err := regexp.Compile(t.Filter)
+		if err != nil {
+			return target{}, fmt.Errorf("invalid filter expression %q; cause: %w", t.Filter, err)
+		}
+		resolved.filter = compiled
+	}
+
+	labelFilter, err := provider.NewLabelFilter(t.LabelPass, t.LabelDrop)
+	if err != nil {
+		return target{}, err
+	}
+	resolved.labelFilter = labelFilter
+
+	return resolved, nil
+}
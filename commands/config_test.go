@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResolveTarget(t *testing.T) {
+	fileA := writeTempFile(t, "metrics_a")
+	fileB := writeTempFile(t, "metrics_b")
+
+	t.Run("name defaults to target-<index> when unset", func(t *testing.T) {
+		resolved, err := resolveTarget(targetConfig{File: fileA}, 2, time.Second, "", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resolved.name != "target-2" {
+			t.Errorf("got name %q, want target-2", resolved.name)
+		}
+	})
+
+	t.Run("explicit name is preserved", func(t *testing.T) {
+		resolved, err := resolveTarget(targetConfig{Name: "primary", File: fileA}, 0, time.Second, "", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resolved.name != "primary" {
+			t.Errorf("got name %q, want primary", resolved.name)
+		}
+	})
+
+	t.Run("falls back to the default interval when none is set", func(t *testing.T) {
+		resolved, err := resolveTarget(targetConfig{File: fileA}, 0, 7*time.Second, "", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resolved.interval != 7*time.Second {
+			t.Errorf("got interval %s, want 7s", resolved.interval)
+		}
+	})
+
+	t.Run("an explicit interval overrides the default", func(t *testing.T) {
+		resolved, err := resolveTarget(targetConfig{File: fileA, Interval: "2"}, 0, 7*time.Second, "", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resolved.interval != 2*time.Second {
+			t.Errorf("got interval %s, want 2s", resolved.interval)
+		}
+	})
+
+	t.Run("tags are carried through to the resolved target", func(t *testing.T) {
+		tags := map[string]string{"region": "us-east"}
+		resolved, err := resolveTarget(targetConfig{File: fileA, Tags: tags}, 0, time.Second, "", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resolved.tags["region"] != "us-east" {
+			t.Errorf("got tags %v, want region=us-east", resolved.tags)
+		}
+	})
+
+	t.Run("no source flag is rejected", func(t *testing.T) {
+		if _, err := resolveTarget(targetConfig{}, 0, time.Second, "", nil); err == nil {
+			t.Fatal("expected an error when no source flag is set")
+		}
+	})
+
+	t.Run("more than one source flag is rejected", func(t *testing.T) {
+		if _, err := resolveTarget(targetConfig{File: fileA, Url: "http://example.com"}, 0, time.Second, "", nil); err == nil {
+			t.Fatal("expected an error when multiple source flags are set")
+		}
+	})
+
+	t.Run("an invalid filter regex is rejected", func(t *testing.T) {
+		if _, err := resolveTarget(targetConfig{File: fileA, Filter: "("}, 0, time.Second, "", nil); err == nil {
+			t.Fatal("expected an error for an invalid filter regex")
+		}
+	})
+
+	t.Run("different sources sharing a cache dir get distinct cache files", func(t *testing.T) {
+		cacheDir := t.TempDir()
+
+		a, err := resolveTarget(targetConfig{Name: "same-name", File: fileA}, 0, time.Second, cacheDir, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		b, err := resolveTarget(targetConfig{Name: "same-name", File: fileB}, 0, time.Second, cacheDir, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := a.urlMetricsFetcher.FetchMetrics(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := b.urlMetricsFetcher.FetchMetrics(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		entries, err := ioutil.ReadDir(cacheDir)
+		if err != nil {
+			t.Fatalf("could not list cache dir: %v", err)
+		}
+		if len(entries) != 2 {
+			t.Errorf("expected distinct cache files for distinct sources, got %d file(s) in the cache dir", len(entries))
+		}
+	})
+}
+
+func TestLoadTargetsConfig(t *testing.T) {
+	fileA := writeTempFile(t, "metrics_a")
+
+	t.Run("rejects a config file declaring no targets", func(t *testing.T) {
+		path := writeTempConfig(t, "targets: []\n")
+		if _, err := loadTargetsConfig(path, time.Second, "", nil); err == nil {
+			t.Fatal("expected an error for an empty targets list")
+		}
+	})
+
+	t.Run("rejects duplicate target names", func(t *testing.T) {
+		path := writeTempConfig(t, "targets:\n  - name: dup\n    file: "+fileA+"\n  - name: dup\n    file: "+fileA+"\n")
+		if _, err := loadTargetsConfig(path, time.Second, "", nil); err == nil {
+			t.Fatal("expected an error for duplicate target names")
+		}
+	})
+
+	t.Run("resolves every declared target", func(t *testing.T) {
+		path := writeTempConfig(t, "targets:\n  - name: primary\n    file: "+fileA+"\n  - name: replica\n    file: "+fileA+"\n")
+		targets, err := loadTargetsConfig(path, time.Second, "", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(targets) != 2 {
+			t.Fatalf("got %d targets, want 2", len(targets))
+		}
+	})
+}
+
+func writeTempFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "metrics.prom")
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("could not write temp file: %v", err)
+	}
+	return path
+}
+
+func writeTempConfig(t *testing.T, contents string) string {
+	t.Helper()
+	f, err := ioutil.TempFile(t.TempDir(), "config-*.yaml")
+	if err != nil {
+		t.Fatalf("could not create temp config file: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatalf("could not write temp config file: %v", err)
+	}
+	return f.Name()
+}
@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/eldada/metrics-viewer/provider"
+	"github.com/jfrog/jfrog-cli-core/plugins/components"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+var OutputFlag = components.StringFlag{
+	Name:         "output",
+	Description:  "file to write the snapshot to, or '-' for stdout",
+	DefaultValue: "-",
+}
+
+var DurationFlag = components.StringFlag{
+	Name:        "duration",
+	Description: "total time to scrape for, e.g. '30s' or '5m'; mutually exclusive with --samples",
+}
+
+var SamplesFlag = components.StringFlag{
+	Name:        "samples",
+	Description: "number of scrape intervals to capture before exiting; mutually exclusive with --duration",
+}
+
+// GetSnapshotCommand returns the "snapshot" command: a non-interactive
+// counterpart to the viewer that performs a bounded scrape and writes the
+// collected, filtered and aggregated metrics to a file in a stable,
+// machine-readable form, suitable for attaching to a support ticket.
+func GetSnapshotCommand() components.Command {
+	return components.Command{
+		Name:        "snapshot",
+		Description: "scrape metrics for a bounded window and dump them to stdout or a file, without opening the interactive viewer",
+		Aliases:     []string{"dump"},
+		Flags:       append(getCommonFlags(), OutputFlag, DurationFlag, SamplesFlag),
+		Action:      snapshotCmd,
+	}
+}
+
+// snapshotRecord is one line of the JSON lines output: a single target's
+// scrape, timestamped for later correlation across targets. Metrics carries
+// the original OpenMetrics payload as fetched, while Samples carries that
+// same scrape after the target's metric-name filter, label filter and
+// aggregation have been applied, mirroring what the interactive viewer would
+// display.
+type snapshotRecord struct {
+	Target    string           `json:"target"`
+	Timestamp string           `json:"timestamp"`
+	Metrics   string           `json:"metrics"`
+	Samples   []snapshotSample `json:"samples"`
+}
+
+type snapshotSample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+func snapshotCmd(c *components.Context) error {
+	conf, err := parseCommonConfig(c)
+	if err != nil {
+		return err
+	}
+
+	targets := conf.Targets()
+	tick := targets[0].interval
+	for _, t := range targets[1:] {
+		if t.interval < tick {
+			tick = t.interval
+		}
+	}
+
+	rounds, err := parseSnapshotBounds(c.GetStringFlagValue("duration"), c.GetStringFlagValue("samples"), tick)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	outputPath := c.GetStringFlagValue("output")
+	if outputPath != "" && outputPath != "-" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("could not create output file %s: %w", outputPath, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	nextDue := make([]time.Time, len(targets))
+	now := time.Now()
+	for i := range targets {
+		nextDue[i] = now
+	}
+
+	for round := 0; round < rounds; round++ {
+		now := time.Now()
+		for i, t := range targets {
+			if now.Before(nextDue[i]) {
+				continue
+			}
+			if err := writeSnapshot(out, t, conf.AggregateIgnoreLabels()); err != nil {
+				return err
+			}
+			nextDue[i] = now.Add(t.interval)
+		}
+		if round < rounds-1 {
+			time.Sleep(tick)
+		}
+	}
+
+	return nil
+}
+
+func writeSnapshot(out io.Writer, t target, aggregateIgnoreLabels provider.StringSet) error {
+	metrics, err := t.urlMetricsFetcher.FetchMetrics()
+	if err != nil {
+		return fmt.Errorf("could not scrape target %s: %w", t.name, err)
+	}
+
+	parsed, err := provider.ParseSamples(metrics)
+	if err != nil {
+		return fmt.Errorf("could not parse metrics scraped from target %s: %w", t.name, err)
+	}
+
+	filtered := provider.FilterSamples(parsed, t.filter, t.labelFilter)
+	aggregated := provider.Aggregate(filtered, aggregateIgnoreLabels)
+
+	record := snapshotRecord{
+		Target:    t.name,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Metrics:   metrics,
+		Samples:   toSnapshotSamples(aggregated, t.tags),
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("could not encode snapshot for target %s: %w", t.name, err)
+	}
+
+	_, err = fmt.Fprintln(out, string(line))
+	return err
+}
+
+// toSnapshotSamples converts aggregated provider samples to their JSON
+// representation, attaching the target's static tags (declared via --config)
+// to every emitted sample's label set.
+func toSnapshotSamples(samples []provider.Sample, tags map[string]string) []snapshotSample {
+	out := make([]snapshotSample, 0, len(samples))
+	for _, s := range samples {
+		labels := s.Labels
+		if len(tags) > 0 {
+			labels = make(map[string]string, len(s.Labels)+len(tags))
+			for k, v := range s.Labels {
+				labels[k] = v
+			}
+			for k, v := range tags {
+				labels[k] = v
+			}
+		}
+		out = append(out, snapshotSample{Name: s.Name, Labels: labels, Value: s.Value})
+	}
+	return out
+}
+
+// parseSnapshotBounds resolves the --duration/--samples flag values into a
+// round count. At most one of them may be given; neither given defaults to a
+// single round, matching a plain one-shot scrape.
+func parseSnapshotBounds(durationValue, samplesValue string, interval time.Duration) (int, error) {
+	if durationValue != "" && samplesValue != "" {
+		return 0, fmt.Errorf("only one flag is allowed: --duration | --samples")
+	}
+
+	if durationValue != "" {
+		duration, err := time.ParseDuration(durationValue)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse duration value: %s; cause: %w", durationValue, err)
+		}
+		if duration <= 0 {
+			return 0, fmt.Errorf("duration value must be positive; got: %s", duration)
+		}
+		samples := int(duration / interval)
+		if samples < 1 {
+			samples = 1
+		}
+		return samples, nil
+	}
+
+	if samplesValue != "" {
+		samples, err := parsePositiveInt(samplesValue, "samples")
+		if err != nil {
+			return 0, err
+		}
+		return samples, nil
+	}
+
+	return 1, nil
+}
+
+func parsePositiveInt(value, name string) (int, error) {
+	result, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s value: %s; cause: %w", name, value, err)
+	}
+	if result <= 0 {
+		return 0, fmt.Errorf("%s value must be positive; got: %d", name, result)
+	}
+	return int(result), nil
+}
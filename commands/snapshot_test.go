@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSnapshotBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration string
+		samples  string
+		interval time.Duration
+		want     int
+		wantErr  bool
+	}{
+		{name: "neither flag defaults to a single round", interval: time.Second, want: 1},
+		{name: "samples flag is used verbatim", samples: "3", interval: time.Second, want: 3},
+		{name: "duration is divided by the tick interval", duration: "30s", interval: 5 * time.Second, want: 6},
+		{name: "duration shorter than the interval still yields one round", duration: "1s", interval: 5 * time.Second, want: 1},
+		{name: "both flags are mutually exclusive", duration: "30s", samples: "3", interval: time.Second, wantErr: true},
+		{name: "zero duration is rejected", duration: "0s", interval: time.Second, wantErr: true},
+		{name: "non-positive samples is rejected", samples: "0", interval: time.Second, wantErr: true},
+		{name: "unparseable duration is rejected", duration: "not-a-duration", interval: time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSnapshotBounds(tt.duration, tt.samples, tt.interval)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got rounds=%d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %d rounds, want %d", got, tt.want)
+			}
+		})
+	}
+}
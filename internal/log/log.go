@@ -0,0 +1,100 @@
+// Package log provides a small leveled logger used across the CLI to emit
+// diagnostic records (scrape failures, cache fallbacks, config resolution) that are
+// not themselves returned as errors to the jfrog-cli-core command framework.
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Level is a logging severity, ordered from least to most severe.
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+)
+
+func (l Level) String() string {
+	switch l {
+	case Debug:
+		return "DEBUG"
+	case Info:
+		return "INFO"
+	case Warn:
+		return "WARN"
+	case Error:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ParseLevel parses the --log-level flag value, defaulting to Info for an
+// empty string.
+func ParseLevel(value string) (Level, error) {
+	switch strings.ToUpper(value) {
+	case "", "INFO":
+		return Info, nil
+	case "DEBUG":
+		return Debug, nil
+	case "WARN", "WARNING":
+		return Warn, nil
+	case "ERROR":
+		return Error, nil
+	default:
+		return Info, fmt.Errorf("unknown log level: %s", value)
+	}
+}
+
+// Logger is a minimal leveled logger with pluggable output. Every record is
+// written to the output writer as one plain text line (timestamp, level,
+// message); there is no JSON record format.
+type Logger struct {
+	level  Level
+	output io.Writer
+}
+
+// New creates a Logger that writes records at or above level to output.
+func New(level Level, output io.Writer) *Logger {
+	return &Logger{level: level, output: output}
+}
+
+// NewFromFlags builds a Logger from the --log-level/--log-file flag values,
+// defaulting output to stderr when logFile is empty.
+func NewFromFlags(logLevel, logFile string) (*Logger, error) {
+	level, err := ParseLevel(logLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	output := io.Writer(os.Stderr)
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("could not open log file %s: %w", logFile, err)
+		}
+		output = f
+	}
+
+	return New(level, output), nil
+}
+
+func (l *Logger) log(level Level, format string, args ...interface{}) {
+	if l == nil || level < l.level {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	fmt.Fprintf(l.output, "%s %-5s %s\n", time.Now().Format(time.RFC3339), level, msg)
+}
+
+func (l *Logger) Debug(format string, args ...interface{}) { l.log(Debug, format, args...) }
+func (l *Logger) Info(format string, args ...interface{})  { l.log(Info, format, args...) }
+func (l *Logger) Warn(format string, args ...interface{})  { l.log(Warn, format, args...) }
+func (l *Logger) Error(format string, args ...interface{}) { l.log(Error, format, args...) }
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/eldada/metrics-viewer/internal/log"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// maxConsecutiveFailures is how many consecutive fetch errors are tolerated
+// before cachingFetcher falls back to replaying the last cached snapshot.
+const maxConsecutiveFailures = 3
+
+// cachingFetcher wraps an UrlMetricsFetcher and persists every successful
+// scrape to a rolling file under cacheDir, so the viewer can fall back to
+// the last known-good snapshot when the endpoint becomes unavailable, e.g.
+// to support post-mortem review of an Artifactory server that has since
+// gone down.
+type cachingFetcher struct {
+	delegate            UrlMetricsFetcher
+	cacheFile           string
+	logger              *log.Logger
+	consecutiveFailures int
+}
+
+// NewCachingFetcher layers on-disk caching and fallback on top of any
+// existing fetcher. name identifies the snapshot file within cacheDir so
+// that multiple targets sharing a cache directory don't collide. logger may
+// be nil, in which case cache events are not reported anywhere.
+func NewCachingFetcher(delegate UrlMetricsFetcher, cacheDir, name string, logger *log.Logger) (UrlMetricsFetcher, error) {
+	info, err := os.Stat(cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("could not access cache directory %s: %w", cacheDir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("cache directory %s is not a directory", cacheDir)
+	}
+
+	return &cachingFetcher{
+		delegate:  delegate,
+		cacheFile: filepath.Join(cacheDir, name+".cache"),
+		logger:    logger,
+	}, nil
+}
+
+func (f *cachingFetcher) FetchMetrics() (string, error) {
+	metrics, err := f.delegate.FetchMetrics()
+	if err == nil {
+		f.consecutiveFailures = 0
+		if writeErr := ioutil.WriteFile(f.cacheFile, []byte(metrics), 0644); writeErr != nil {
+			return metrics, fmt.Errorf("fetched metrics but failed to update cache file %s: %w", f.cacheFile, writeErr)
+		}
+		f.logger.Debug("wrote cache snapshot for %s to %s", f.delegate, f.cacheFile)
+		return metrics, nil
+	}
+
+	f.consecutiveFailures++
+	f.logger.Warn("scrape of %s failed (%d consecutive failures): %v", f.delegate, f.consecutiveFailures, err)
+	if f.consecutiveFailures < maxConsecutiveFailures {
+		return "", err
+	}
+
+	cached, cacheErr := ioutil.ReadFile(f.cacheFile)
+	if cacheErr != nil {
+		return "", fmt.Errorf("fetch failed %d times in a row (%w) and no cached snapshot is available at %s: %w",
+			f.consecutiveFailures, err, f.cacheFile, cacheErr)
+	}
+
+	f.logger.Warn("falling back to cached snapshot at %s after %d consecutive failures", f.cacheFile, f.consecutiveFailures)
+	return string(cached), nil
+}
+
+func (f *cachingFetcher) String() string {
+	return fmt.Sprintf("%s (cached at %s)", f.delegate, f.cacheFile)
+}
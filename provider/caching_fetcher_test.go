@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+)
+
+// fakeFetcher fails its first failUntil calls, then succeeds, returning value.
+type fakeFetcher struct {
+	failUntil int
+	calls     int
+	value     string
+}
+
+func (f *fakeFetcher) FetchMetrics() (string, error) {
+	f.calls++
+	if f.calls <= f.failUntil {
+		return "", fmt.Errorf("scrape failed (call %d)", f.calls)
+	}
+	return f.value, nil
+}
+
+func (f *fakeFetcher) String() string {
+	return "fake"
+}
+
+func TestCachingFetcherFetchMetrics(t *testing.T) {
+	t.Run("a successful fetch is written to the cache file", func(t *testing.T) {
+		delegate := &fakeFetcher{value: "metric_a 1"}
+		fetcher, err := NewCachingFetcher(delegate, t.TempDir(), "target", nil)
+		if err != nil {
+			t.Fatalf("NewCachingFetcher returned an error: %v", err)
+		}
+
+		got, err := fetcher.FetchMetrics()
+		if err != nil {
+			t.Fatalf("FetchMetrics returned an error: %v", err)
+		}
+		if got != "metric_a 1" {
+			t.Errorf("got %q, want metric_a 1", got)
+		}
+	})
+
+	t.Run("failures below the threshold are returned as errors, not masked by the cache", func(t *testing.T) {
+		delegate := &fakeFetcher{failUntil: maxConsecutiveFailures - 1, value: "metric_a 1"}
+		cacheDir := t.TempDir()
+		fetcher, err := NewCachingFetcher(delegate, cacheDir, "target", nil)
+		if err != nil {
+			t.Fatalf("NewCachingFetcher returned an error: %v", err)
+		}
+
+		// Prime the cache with a prior successful snapshot.
+		primed, err := NewCachingFetcher(&fakeFetcher{value: "metric_a 0"}, cacheDir, "target", nil)
+		if err != nil {
+			t.Fatalf("NewCachingFetcher returned an error: %v", err)
+		}
+		if _, err := primed.FetchMetrics(); err != nil {
+			t.Fatalf("priming fetch returned an error: %v", err)
+		}
+
+		for i := 0; i < maxConsecutiveFailures-1; i++ {
+			if _, err := fetcher.FetchMetrics(); err == nil {
+				t.Fatalf("call %d: expected an error below the failure threshold, got none", i+1)
+			}
+		}
+	})
+
+	t.Run("reaching the failure threshold falls back to the last cached snapshot", func(t *testing.T) {
+		cacheDir := t.TempDir()
+
+		// Prime the cache with a successful scrape.
+		priming := &fakeFetcher{value: "metric_a 1"}
+		primed, err := NewCachingFetcher(priming, cacheDir, "target", nil)
+		if err != nil {
+			t.Fatalf("NewCachingFetcher returned an error: %v", err)
+		}
+		if _, err := primed.FetchMetrics(); err != nil {
+			t.Fatalf("priming fetch returned an error: %v", err)
+		}
+
+		failing := &fakeFetcher{failUntil: maxConsecutiveFailures + 1}
+		fetcher, err := NewCachingFetcher(failing, cacheDir, "target", nil)
+		if err != nil {
+			t.Fatalf("NewCachingFetcher returned an error: %v", err)
+		}
+
+		var got string
+		for i := 0; i < maxConsecutiveFailures; i++ {
+			got, err = fetcher.FetchMetrics()
+		}
+		if err != nil {
+			t.Fatalf("expected the fallback cached snapshot to be returned without error, got: %v", err)
+		}
+		if got != "metric_a 1" {
+			t.Errorf("got %q, want the cached snapshot metric_a 1", got)
+		}
+	})
+
+	t.Run("reaching the failure threshold with no cached snapshot yet returns an error", func(t *testing.T) {
+		delegate := &fakeFetcher{failUntil: maxConsecutiveFailures}
+		fetcher, err := NewCachingFetcher(delegate, t.TempDir(), "target", nil)
+		if err != nil {
+			t.Fatalf("NewCachingFetcher returned an error: %v", err)
+		}
+
+		var fetchErr error
+		for i := 0; i < maxConsecutiveFailures; i++ {
+			_, fetchErr = fetcher.FetchMetrics()
+		}
+		if fetchErr == nil {
+			t.Fatal("expected an error when no cached snapshot is available to fall back to")
+		}
+	})
+
+	t.Run("a success after falling back is reported fresh, not replayed from the cache", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		priming := &fakeFetcher{value: "metric_a 1"}
+		primed, err := NewCachingFetcher(priming, cacheDir, "target", nil)
+		if err != nil {
+			t.Fatalf("NewCachingFetcher returned an error: %v", err)
+		}
+		if _, err := primed.FetchMetrics(); err != nil {
+			t.Fatalf("priming fetch returned an error: %v", err)
+		}
+
+		delegate := &fakeFetcher{failUntil: maxConsecutiveFailures, value: "metric_a 2"}
+		fetcher, err := NewCachingFetcher(delegate, cacheDir, "target", nil)
+		if err != nil {
+			t.Fatalf("NewCachingFetcher returned an error: %v", err)
+		}
+
+		// The first maxConsecutiveFailures-1 calls fail outright; the call
+		// that reaches the threshold falls back to the primed cache.
+		for i := 0; i < maxConsecutiveFailures-1; i++ {
+			if _, err := fetcher.FetchMetrics(); err == nil {
+				t.Fatalf("call %d: expected an error below the failure threshold, got none", i+1)
+			}
+		}
+		if got, err := fetcher.FetchMetrics(); err != nil || got != "metric_a 1" {
+			t.Fatalf("threshold call: got (%q, %v), want the cached metric_a 1 with no error", got, err)
+		}
+
+		got, err := fetcher.FetchMetrics()
+		if err != nil {
+			t.Fatalf("unexpected error on recovered fetch: %v", err)
+		}
+		if got != "metric_a 2" {
+			t.Errorf("got %q, want the freshly scraped metric_a 2", got)
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"fmt"
+	"io/ioutil"
+)
+
+// fileMetricsFetcher reads a static OpenMetrics payload from a local file on
+// every fetch, as used by the --file flag.
+type fileMetricsFetcher struct {
+	path string
+}
+
+// NewFileMetricsFetcher returns an UrlMetricsFetcher that reads its metrics
+// from the file at path on every call to FetchMetrics.
+func NewFileMetricsFetcher(path string) UrlMetricsFetcher {
+	return &fileMetricsFetcher{path: path}
+}
+
+func (f *fileMetricsFetcher) FetchMetrics() (string, error) {
+	raw, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("could not read file %s: %w", f.path, err)
+	}
+	return string(raw), nil
+}
+
+func (f *fileMetricsFetcher) String() string {
+	return fmt.Sprintf("file: %s", f.path)
+}
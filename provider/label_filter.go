@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LabelFilter implements the Telegraf-style tagpass/tagdrop semantics: a
+// sample must match every tagpass condition (AND across labels) and must not
+// match any tagdrop condition, which is evaluated after tagpass. Matching
+// multiple values for a single label ("OR'ed") is expressed with ordinary
+// regex alternation (e.g. "label=v1|v2"), so a comma inside the pattern
+// (e.g. a bounded quantifier like \d{1,3}) is never ambiguous with a
+// delimiter.
+type LabelFilter struct {
+	pass []labelCondition
+	drop []labelCondition
+}
+
+type labelCondition struct {
+	label string
+	value *regexp.Regexp
+}
+
+// NewLabelFilter compiles the --label-pass and --label-drop flag values into
+// a reusable LabelFilter. Each entry follows the "label=regex" syntax;
+// multiple entries are semicolon delimited.
+func NewLabelFilter(labelPass, labelDrop string) (LabelFilter, error) {
+	pass, err := parseLabelConditions(labelPass)
+	if err != nil {
+		return LabelFilter{}, fmt.Errorf("invalid label-pass value %q: %w", labelPass, err)
+	}
+	drop, err := parseLabelConditions(labelDrop)
+	if err != nil {
+		return LabelFilter{}, fmt.Errorf("invalid label-drop value %q: %w", labelDrop, err)
+	}
+	return LabelFilter{pass: pass, drop: drop}, nil
+}
+
+func parseLabelConditions(value string) ([]labelCondition, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var conditions []labelCondition
+	for _, entry := range strings.Split(value, ";") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("expected label=regex, got %q", entry)
+		}
+		label, pattern := parts[0], parts[1]
+
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q for label %q: %w", pattern, label, err)
+		}
+		conditions = append(conditions, labelCondition{label: label, value: re})
+	}
+
+	return conditions, nil
+}
+
+// Matches reports whether the given sample labels satisfy the filter: every
+// tagpass condition must have at least one matching value, and no tagdrop
+// condition may match.
+func (f LabelFilter) Matches(labels map[string]string) bool {
+	for _, cond := range f.pass {
+		if !cond.matches(labels) {
+			return false
+		}
+	}
+	for _, cond := range f.drop {
+		if cond.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c labelCondition) matches(labels map[string]string) bool {
+	value, ok := labels[c.label]
+	if !ok {
+		return false
+	}
+	return c.value.MatchString(value)
+}
+
+// String reports how many tagpass/tagdrop conditions are configured, for use
+// in diagnostic output; it does not reproduce the original regex patterns.
+func (f LabelFilter) String() string {
+	return fmt.Sprintf("%d label-pass, %d label-drop condition(s)", len(f.pass), len(f.drop))
+}
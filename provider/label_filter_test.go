@@ -0,0 +1,95 @@
+package provider
+
+import "testing"
+
+func TestNewLabelFilterMatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		labelPass string
+		labelDrop string
+		labels    map[string]string
+		want      bool
+	}{
+		{
+			name:   "no conditions passes everything",
+			labels: map[string]string{"repo": "libs-release"},
+			want:   true,
+		},
+		{
+			name:      "tagpass requires a match",
+			labelPass: "repo=libs-release",
+			labels:    map[string]string{"repo": "libs-snapshot"},
+			want:      false,
+		},
+		{
+			name:      "tagpass values are OR'ed via regex alternation",
+			labelPass: "repo=libs-release|libs-snapshot",
+			labels:    map[string]string{"repo": "libs-snapshot"},
+			want:      true,
+		},
+		{
+			name:      "tagpass conditions across labels are AND'ed",
+			labelPass: "repo=libs-release;status=200",
+			labels:    map[string]string{"repo": "libs-release", "status": "404"},
+			want:      false,
+		},
+		{
+			name:      "tagdrop is applied after tagpass",
+			labelPass: "repo=libs-release",
+			labelDrop: "status=500",
+			labels:    map[string]string{"repo": "libs-release", "status": "500"},
+			want:      false,
+		},
+		{
+			name:      "a comma inside a regex quantifier is not split as a value delimiter",
+			labelPass: `duration=\d{1,3}`,
+			labels:    map[string]string{"duration": "42"},
+			want:      true,
+		},
+		{
+			name:      "missing label never matches",
+			labelPass: "repo=libs-release",
+			labels:    map[string]string{},
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewLabelFilter(tt.labelPass, tt.labelDrop)
+			if err != nil {
+				t.Fatalf("NewLabelFilter returned an error: %v", err)
+			}
+			if got := filter.Matches(tt.labels); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLabelConditionsRejectsMalformedEntries(t *testing.T) {
+	tests := []string{
+		"repo",
+		"=libs-release",
+		"repo=",
+		"repo=(unclosed",
+	}
+
+	for _, value := range tests {
+		if _, err := parseLabelConditions(value); err == nil {
+			t.Errorf("parseLabelConditions(%q) expected an error, got none", value)
+		}
+	}
+}
+
+func TestLabelFilterStringReportsConditionCounts(t *testing.T) {
+	filter, err := NewLabelFilter("repo=libs-release", "status=500;env=prod")
+	if err != nil {
+		t.Fatalf("NewLabelFilter returned an error: %v", err)
+	}
+
+	want := "1 label-pass, 2 label-drop condition(s)"
+	if got := filter.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
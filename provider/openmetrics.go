@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sample is a single parsed OpenMetrics exposition line: a metric name, its
+// label set, and its numeric value.
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+var sampleLinePattern = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{(.*)\})?\s+(\S+)`)
+var labelPairPattern = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)="((?:[^"\\]|\\.)*)"`)
+
+// ParseSamples parses an OpenMetrics/Prometheus text exposition payload into
+// samples, skipping comment (#) and blank lines.
+func ParseSamples(raw string) ([]Sample, error) {
+	var samples []Sample
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		match := sampleLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(match[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse value in sample line %q: %w", line, err)
+		}
+
+		labels := map[string]string{}
+		for _, pair := range labelPairPattern.FindAllStringSubmatch(match[3], -1) {
+			labels[pair[1]] = pair[2]
+		}
+
+		samples = append(samples, Sample{Name: match[1], Labels: labels, Value: value})
+	}
+	return samples, nil
+}
+
+// FilterSamples keeps only the samples whose name matches the filter regexp
+// and whose labels satisfy the label filter.
+func FilterSamples(samples []Sample, filter *regexp.Regexp, labelFilter LabelFilter) []Sample {
+	kept := make([]Sample, 0, len(samples))
+	for _, s := range samples {
+		if filter != nil && !filter.MatchString(s.Name) {
+			continue
+		}
+		if !labelFilter.Matches(s.Labels) {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	return kept
+}
+
+// Aggregate sums samples that share a name once the labels in ignoreLabels
+// are dropped from their label set, mirroring the existing aggregation
+// performed for the interactive viewer. ignoreLabels may contain "ALL" to
+// drop every label.
+func Aggregate(samples []Sample, ignoreLabels StringSet) []Sample {
+	ignoreAll := ignoreLabels.Contains("ALL")
+
+	type aggregateKey struct {
+		name   string
+		labels string
+	}
+
+	order := make([]aggregateKey, 0, len(samples))
+	grouped := map[aggregateKey]*Sample{}
+
+	for _, s := range samples {
+		remaining := map[string]string{}
+		if !ignoreAll {
+			for label, value := range s.Labels {
+				if ignoreLabels.Contains(label) {
+					continue
+				}
+				remaining[label] = value
+			}
+		}
+
+		key := aggregateKey{name: s.Name, labels: labelSetKey(remaining)}
+		if existing, ok := grouped[key]; ok {
+			existing.Value += s.Value
+			continue
+		}
+
+		aggregated := Sample{Name: s.Name, Labels: remaining, Value: s.Value}
+		grouped[key] = &aggregated
+		order = append(order, key)
+	}
+
+	result := make([]Sample, 0, len(order))
+	for _, key := range order {
+		result = append(result, *grouped[key])
+	}
+	return result
+}
+
+// labelSetKey produces a stable string representation of a label set so it
+// can be used as a map key regardless of iteration order.
+func labelSetKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(labels[name])
+		b.WriteByte(';')
+	}
+	return b.String()
+}